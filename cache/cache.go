@@ -0,0 +1,54 @@
+// Package cache stores resolved video metadata and bytes so repeat requests
+// for the same source URL skip re-invoking the extractor pipeline, which is
+// slow and prone to rate limiting.
+package cache
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/tiarhax/instastreamer_go/extractors"
+)
+
+// Cache stores resolved video metadata and bytes so repeat requests for the
+// same source URL skip re-invoking the extractor pipeline.
+//
+// Metadata (the direct CDN URL) is short-lived, so it's cached with an
+// explicit TTL. Bytes are cached without a TTL, relying
+// on the backing store's own expiry (e.g. an S3 lifecycle rule), since the
+// bytes themselves remain valid long after the signed URL that produced
+// them expires.
+type Cache interface {
+	GetInfo(ctx context.Context, url string) (*extractors.VideoInfo, bool)
+	PutInfo(ctx context.Context, url string, info *extractors.VideoInfo, ttl time.Duration) error
+
+	GetStream(ctx context.Context, url string) (io.ReadCloser, bool)
+	PutStream(ctx context.Context, url string, r io.Reader) error
+}
+
+// Store composes a DynamoInfoCache and S3StreamCache into a single Cache.
+type Store struct {
+	info   *DynamoInfoCache
+	stream *S3StreamCache
+}
+
+func NewStore(info *DynamoInfoCache, stream *S3StreamCache) *Store {
+	return &Store{info: info, stream: stream}
+}
+
+func (s *Store) GetInfo(ctx context.Context, url string) (*extractors.VideoInfo, bool) {
+	return s.info.GetInfo(ctx, url)
+}
+
+func (s *Store) PutInfo(ctx context.Context, url string, info *extractors.VideoInfo, ttl time.Duration) error {
+	return s.info.PutInfo(ctx, url, info, ttl)
+}
+
+func (s *Store) GetStream(ctx context.Context, url string) (io.ReadCloser, bool) {
+	return s.stream.GetStream(ctx, url)
+}
+
+func (s *Store) PutStream(ctx context.Context, url string, r io.Reader) error {
+	return s.stream.PutStream(ctx, url, r)
+}
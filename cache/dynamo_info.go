@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/tiarhax/instastreamer_go/extractors"
+)
+
+// DynamoInfoCache caches resolved VideoInfo in DynamoDB, keyed on
+// SHA256(url), relying on the table's TTL attribute (on "expires_at") for
+// expiry.
+type DynamoInfoCache struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewDynamoInfoCache(client *dynamodb.Client, tableName string) *DynamoInfoCache {
+	return &DynamoInfoCache{client: client, tableName: tableName}
+}
+
+func infoCacheKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *DynamoInfoCache) GetInfo(ctx context.Context, url string) (*extractors.VideoInfo, bool) {
+	result, err := c.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(c.tableName),
+		Key: map[string]types.AttributeValue{
+			"url_hash": &types.AttributeValueMemberS{Value: infoCacheKey(url)},
+		},
+	})
+	if err != nil || result.Item == nil {
+		return nil, false
+	}
+
+	if expiresAt, ok := result.Item["expires_at"].(*types.AttributeValueMemberN); ok {
+		if exp, err := strconv.ParseInt(expiresAt.Value, 10, 64); err == nil && time.Now().Unix() >= exp {
+			// DynamoDB's native TTL sweep can lag well behind the
+			// timestamp (AWS documents delays of up to 48h), so we
+			// can't rely on GetItem alone to hide expired items.
+			return nil, false
+		}
+	}
+
+	payload, ok := result.Item["payload"].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, false
+	}
+
+	var info extractors.VideoInfo
+	if err := json.Unmarshal([]byte(payload.Value), &info); err != nil {
+		return nil, false
+	}
+
+	return &info, true
+}
+
+func (c *DynamoInfoCache) PutInfo(ctx context.Context, url string, info *extractors.VideoInfo, ttl time.Duration) error {
+	payload, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("cache: marshal video info: %w", err)
+	}
+
+	_, err = c.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(c.tableName),
+		Item: map[string]types.AttributeValue{
+			"url_hash":   &types.AttributeValueMemberS{Value: infoCacheKey(url)},
+			"payload":    &types.AttributeValueMemberS{Value: string(payload)},
+			"expires_at": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", time.Now().Add(ttl).Unix())},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("cache: put video info: %w", err)
+	}
+
+	return nil
+}
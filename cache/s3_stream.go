@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3StreamCache caches resolved video bytes in S3. Instagram's CDN URLs are
+// short-lived signed URLs, so we cache the bytes themselves rather than the
+// URL; object expiry is handled by an S3 lifecycle rule on the bucket.
+type S3StreamCache struct {
+	client *s3.Client
+	bucket string
+}
+
+func NewS3StreamCache(client *s3.Client, bucket string) *S3StreamCache {
+	return &S3StreamCache{client: client, bucket: bucket}
+}
+
+func streamCacheKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:]) + ".mp4"
+}
+
+func (c *S3StreamCache) GetStream(ctx context.Context, url string) (io.ReadCloser, bool) {
+	out, err := c.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(streamCacheKey(url)),
+	})
+	if err != nil {
+		return nil, false
+	}
+
+	return out.Body, true
+}
+
+func (c *S3StreamCache) PutStream(ctx context.Context, url string, r io.Reader) error {
+	// Buffered to memory so Content-Length can be set; these are short
+	// reels/clips, not long-form video.
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("cache: read stream: %w", err)
+	}
+
+	_, err = c.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(c.bucket),
+		Key:         aws.String(streamCacheKey(url)),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("video/mp4"),
+		Metadata: map[string]string{
+			"original-url": url,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("cache: put stream: %w", err)
+	}
+
+	return nil
+}
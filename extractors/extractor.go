@@ -0,0 +1,19 @@
+// Package extractors provides pluggable video-resolution backends for the
+// hosts instastreamer supports (Instagram, TikTok, X/Twitter, YouTube).
+package extractors
+
+import "context"
+
+// VideoInfo describes a resolved video's direct source URL and metadata.
+type VideoInfo struct {
+	URL       string
+	Title     string
+	Extension string
+}
+
+// Extractor resolves metadata for a video page URL, in particular its
+// direct source URL. Implementations should be safe for concurrent use.
+type Extractor interface {
+	// Resolve fetches metadata (direct URL, title, extension) for url.
+	Resolve(ctx context.Context, url string) (*VideoInfo, error)
+}
@@ -0,0 +1,130 @@
+package extractors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+// instagramAppID is the public X-IG-App-ID value used by Instagram's own web
+// client; sending it alongside a browser user agent lets us call the
+// internal API without authenticating.
+const instagramAppID = "936619743392459"
+
+const instagramUserAgent = "Mozilla/5.0 (iPhone; CPU iPhone OS 16_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 Instagram 275.0.0.27.98"
+
+var shortcodePattern = regexp.MustCompile(`instagram\.com/(?:reel|p|tv)/([A-Za-z0-9_-]+)`)
+
+// igMediaResponse mirrors the subset of i.instagram.com/api/v1/media/{shortcode}/info/
+// that we care about.
+type igMediaResponse struct {
+	Items []IgReelItem `json:"items"`
+}
+
+// IgReelItem is a single media item returned by Instagram's private API.
+type IgReelItem struct {
+	VideoVersions []igVideoVersion `json:"video_versions"`
+	Caption       *struct {
+		Text string `json:"text"`
+	} `json:"caption"`
+}
+
+type igVideoVersion struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// InstagramAPIExtractor resolves Instagram reels/posts by calling
+// Instagram's private mobile API directly, bypassing yt-dlp. It is much
+// faster than yt-dlp but more brittle, since it depends on an undocumented
+// endpoint.
+type InstagramAPIExtractor struct {
+	client *http.Client
+}
+
+func NewInstagramAPIExtractor() *InstagramAPIExtractor {
+	return &InstagramAPIExtractor{client: &http.Client{}}
+}
+
+func (e *InstagramAPIExtractor) Resolve(ctx context.Context, videoURL string) (*VideoInfo, error) {
+	item, err := e.fetchItem(ctx, videoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	best := bestVideoVersion(item.VideoVersions)
+	if best == nil {
+		return nil, fmt.Errorf("instagram api: no video_versions in response")
+	}
+
+	title := "video"
+	if item.Caption != nil && item.Caption.Text != "" {
+		title = item.Caption.Text
+	}
+
+	return &VideoInfo{URL: best.URL, Title: title, Extension: "mp4"}, nil
+}
+
+func (e *InstagramAPIExtractor) fetchItem(ctx context.Context, videoURL string) (*IgReelItem, error) {
+	shortcode := extractShortcode(videoURL)
+	if shortcode == "" {
+		return nil, fmt.Errorf("instagram api: could not find shortcode in %q", videoURL)
+	}
+
+	endpoint := fmt.Sprintf("https://i.instagram.com/api/v1/media/%s/info/", shortcode)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("instagram api: build request: %w", err)
+	}
+	req.Header.Set("User-Agent", instagramUserAgent)
+	req.Header.Set("X-IG-App-ID", instagramAppID)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("instagram api: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("instagram api: returned %s", resp.Status)
+	}
+
+	var parsed igMediaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("instagram api: decode response: %w", err)
+	}
+	if len(parsed.Items) == 0 {
+		return nil, fmt.Errorf("instagram api: no items in response")
+	}
+
+	return &parsed.Items[0], nil
+}
+
+// extractShortcode pulls the media shortcode out of a reel/post/tv URL, e.g.
+// "https://www.instagram.com/reel/Cxyz123/" -> "Cxyz123".
+func extractShortcode(videoURL string) string {
+	matches := shortcodePattern.FindStringSubmatch(videoURL)
+	if len(matches) != 2 {
+		return ""
+	}
+	return matches[1]
+}
+
+// bestVideoVersion returns the highest-resolution entry in versions, or nil
+// if versions is empty. Instagram orders these arbitrarily, so we compare by
+// pixel count rather than relying on index 0.
+func bestVideoVersion(versions []igVideoVersion) *igVideoVersion {
+	var best *igVideoVersion
+	bestArea := -1
+	for i := range versions {
+		area := versions[i].Width * versions[i].Height
+		if area > bestArea {
+			bestArea = area
+			best = &versions[i]
+		}
+	}
+	return best
+}
@@ -0,0 +1,48 @@
+package extractors
+
+import "testing"
+
+func TestBestVideoVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []igVideoVersion
+		wantURL  string
+	}{
+		{
+			name:     "empty",
+			versions: nil,
+			wantURL:  "",
+		},
+		{
+			name: "single version",
+			versions: []igVideoVersion{
+				{URL: "only", Width: 480, Height: 854},
+			},
+			wantURL: "only",
+		},
+		{
+			name: "picks highest resolution regardless of order",
+			versions: []igVideoVersion{
+				{URL: "sd", Width: 480, Height: 854},
+				{URL: "hd", Width: 1080, Height: 1920},
+				{URL: "mid", Width: 720, Height: 1280},
+			},
+			wantURL: "hd",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bestVideoVersion(tt.versions)
+			if tt.wantURL == "" {
+				if got != nil {
+					t.Fatalf("bestVideoVersion() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.URL != tt.wantURL {
+				t.Errorf("bestVideoVersion() = %+v, want URL %q", got, tt.wantURL)
+			}
+		})
+	}
+}
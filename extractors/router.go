@@ -0,0 +1,74 @@
+package extractors
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// SupportedHosts lists the domains the router will accept. Anything else is
+// rejected before we ever try to resolve it.
+var SupportedHosts = []string{
+	"instagram.com",
+	"tiktok.com",
+	"x.com",
+	"twitter.com",
+	"youtube.com",
+	"youtu.be",
+}
+
+// Router picks an Extractor for a given URL based on its host. Instagram
+// URLs are tried against the direct API first, falling back to yt-dlp if
+// the API call fails; every other supported host goes straight to yt-dlp.
+type Router struct {
+	instagram Extractor
+	fallback  Extractor
+}
+
+func NewRouter(instagram, fallback Extractor) *Router {
+	return &Router{instagram: instagram, fallback: fallback}
+}
+
+// hostMatches reports whether host is exactly the given domain or a
+// subdomain of it, rejecting lookalikes such as "evil-instagram.com" or
+// "instagram.com.attacker.net" that a plain substring check would accept.
+func hostMatches(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// IsSupportedHost reports whether videoURL's host is one instastreamer knows
+// how to handle.
+func IsSupportedHost(videoURL string) bool {
+	parsed, err := url.Parse(videoURL)
+	if err != nil {
+		return false
+	}
+	host := parsed.Hostname()
+	for _, domain := range SupportedHosts {
+		if hostMatches(host, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) isInstagram(videoURL string) bool {
+	parsed, err := url.Parse(videoURL)
+	if err != nil {
+		return false
+	}
+	return hostMatches(parsed.Hostname(), "instagram.com")
+}
+
+func (r *Router) Resolve(ctx context.Context, videoURL string) (*VideoInfo, error) {
+	if !r.isInstagram(videoURL) {
+		return r.fallback.Resolve(ctx, videoURL)
+	}
+
+	info, err := r.instagram.Resolve(ctx, videoURL)
+	if err == nil {
+		return info, nil
+	}
+
+	return r.fallback.Resolve(ctx, videoURL)
+}
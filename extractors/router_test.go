@@ -0,0 +1,51 @@
+package extractors
+
+import "testing"
+
+func TestHostMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		host   string
+		domain string
+		want   bool
+	}{
+		{"exact match", "instagram.com", "instagram.com", true},
+		{"subdomain", "www.instagram.com", "instagram.com", true},
+		{"deep subdomain", "api.www.instagram.com", "instagram.com", true},
+		{"different domain", "tiktok.com", "instagram.com", false},
+		{"suffix without dot", "evil-instagram.com", "instagram.com", false},
+		{"domain as suffix of attacker host", "instagram.com.attacker.net", "instagram.com", false},
+		{"empty host", "", "instagram.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hostMatches(tt.host, tt.domain); got != tt.want {
+				t.Errorf("hostMatches(%q, %q) = %v, want %v", tt.host, tt.domain, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSupportedHost(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want bool
+	}{
+		{"instagram reel", "https://www.instagram.com/reel/abc123/", true},
+		{"tiktok", "https://www.tiktok.com/@user/video/123", true},
+		{"bare youtu.be", "https://youtu.be/abc123", true},
+		{"unsupported host", "https://vimeo.com/123", false},
+		{"lookalike host", "https://instagram.com.attacker.net/reel/abc123/", false},
+		{"invalid url", "://not-a-url", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSupportedHost(tt.url); got != tt.want {
+				t.Errorf("IsSupportedHost(%q) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
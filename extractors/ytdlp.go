@@ -0,0 +1,72 @@
+package extractors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// YtDlpExtractor shells out to yt-dlp. It works against any host yt-dlp
+// supports and is used as the fallback for hosts without a dedicated API
+// extractor.
+type YtDlpExtractor struct{}
+
+func NewYtDlpExtractor() *YtDlpExtractor {
+	return &YtDlpExtractor{}
+}
+
+func (e *YtDlpExtractor) Resolve(ctx context.Context, videoURL string) (*VideoInfo, error) {
+	cmd := exec.CommandContext(ctx, "yt-dlp", "-j", "--no-warnings", videoURL)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("yt-dlp: %w", err)
+	}
+
+	var info map[string]interface{}
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, fmt.Errorf("yt-dlp: parse video info: %w", err)
+	}
+
+	result := &VideoInfo{Title: "video", Extension: "mp4"}
+	if url, ok := info["url"].(string); ok {
+		result.URL = url
+	}
+	if t, ok := info["title"].(string); ok {
+		result.Title = t
+	}
+	if ex, ok := info["ext"].(string); ok {
+		result.Extension = ex
+	}
+
+	return result, nil
+}
+
+// Format describes one entry of yt-dlp's `-j` formats[] array.
+type Format struct {
+	FormatID string  `json:"format_id"`
+	Ext      string  `json:"ext"`
+	Width    int     `json:"width"`
+	Height   int     `json:"height"`
+	Tbr      float64 `json:"tbr"`
+	URL      string  `json:"url"`
+}
+
+// ListFormats returns every format yt-dlp reports for videoURL, used to
+// build multi-variant HLS playlists.
+func (e *YtDlpExtractor) ListFormats(ctx context.Context, videoURL string) ([]Format, error) {
+	cmd := exec.CommandContext(ctx, "yt-dlp", "-j", "--no-warnings", videoURL)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("yt-dlp: %w", err)
+	}
+
+	var info struct {
+		Formats []Format `json:"formats"`
+	}
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, fmt.Errorf("yt-dlp: parse formats: %w", err)
+	}
+
+	return info.Formats, nil
+}
@@ -0,0 +1,172 @@
+package hls
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tiarhax/instastreamer_go/extractors"
+)
+
+// segmentDuration is the target .ts segment length, in seconds.
+const segmentDuration = 4
+
+// SelectVariants dedupes formats by height, keeping the highest-bitrate
+// format at each resolution, and caps the result at maxVariants so we don't
+// spawn an ffmpeg run per near-duplicate format.
+func SelectVariants(formats []extractors.Format, maxVariants int) []extractors.Format {
+	best := make(map[int]extractors.Format)
+	for _, f := range formats {
+		if f.Height == 0 {
+			continue
+		}
+		if existing, ok := best[f.Height]; !ok || f.Tbr > existing.Tbr {
+			best[f.Height] = f
+		}
+	}
+
+	variants := make([]extractors.Format, 0, len(best))
+	for _, f := range best {
+		variants = append(variants, f)
+	}
+	sort.Slice(variants, func(i, j int) bool { return variants[i].Height > variants[j].Height })
+
+	if len(variants) > maxVariants {
+		variants = variants[:maxVariants]
+	}
+	return variants
+}
+
+// Package packages sourceURL into an HLS session: one rendition per
+// variant, each produced by piping yt-dlp's output for that format through
+// ffmpeg, plus a master playlist with one #EXT-X-STREAM-INF per variant.
+func Package(ctx context.Context, sessionID, sourceURL string, variants []extractors.Format) (*Session, error) {
+	session := &Session{ID: sessionID, Renditions: make(map[string]*Rendition)}
+
+	var master strings.Builder
+	master.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+
+	for _, v := range variants {
+		name := variantName(v)
+		rendition, err := packageRendition(ctx, sourceURL, v.FormatID)
+		if err != nil {
+			return nil, fmt.Errorf("hls: package variant %s: %w", name, err)
+		}
+		session.Renditions[name] = rendition
+
+		bandwidth := int(v.Tbr * 1000)
+		if bandwidth == 0 {
+			bandwidth = 800_000
+		}
+		fmt.Fprintf(&master, "#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d\n%s/playlist.m3u8\n",
+			bandwidth, v.Width, v.Height, name)
+	}
+
+	session.Master = []byte(master.String())
+	return session, nil
+}
+
+func variantName(f extractors.Format) string {
+	return fmt.Sprintf("%dp", f.Height)
+}
+
+// packageRendition runs `yt-dlp -f <formatID> -o -` piped into ffmpeg for a
+// single format, reading the resulting playlist and segments into memory.
+func packageRendition(ctx context.Context, sourceURL, formatID string) (*Rendition, error) {
+	tmpDir, err := os.MkdirTemp("", "instastreamer-hls-*")
+	if err != nil {
+		return nil, fmt.Errorf("mkdir temp: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Run ffmpeg with its working directory set to tmpDir and relative
+	// output names, so the playlist it writes references segments by their
+	// bare filename rather than baking in the temp path.
+	const playlistName = "playlist.m3u8"
+
+	ytdlp := exec.CommandContext(ctx, "yt-dlp", "-f", formatID, "-o", "-", "--no-warnings", sourceURL)
+	ffmpeg := exec.CommandContext(ctx, "ffmpeg",
+		"-i", "-",
+		"-c", "copy",
+		"-f", "hls",
+		"-hls_time", fmt.Sprintf("%d", segmentDuration),
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", "seg%03d.ts",
+		playlistName,
+	)
+	ffmpeg.Dir = tmpDir
+
+	pipe, err := ytdlp.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("yt-dlp stdout pipe: %w", err)
+	}
+	ffmpeg.Stdin = pipe
+
+	ytdlpStderr, err := ytdlp.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("yt-dlp stderr pipe: %w", err)
+	}
+	go logStderr("yt-dlp", ytdlpStderr)
+
+	ffmpegStderr, err := ffmpeg.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg stderr pipe: %w", err)
+	}
+	go logStderr("ffmpeg", ffmpegStderr)
+
+	if err := ffmpeg.Start(); err != nil {
+		return nil, fmt.Errorf("start ffmpeg: %w", err)
+	}
+	if err := ytdlp.Start(); err != nil {
+		return nil, fmt.Errorf("start yt-dlp: %w", err)
+	}
+
+	if err := ytdlp.Wait(); err != nil {
+		return nil, fmt.Errorf("yt-dlp: %w", err)
+	}
+	if err := ffmpeg.Wait(); err != nil {
+		return nil, fmt.Errorf("ffmpeg: %w", err)
+	}
+
+	return readRendition(tmpDir, filepath.Join(tmpDir, playlistName))
+}
+
+func readRendition(tmpDir, playlistPath string) (*Rendition, error) {
+	playlist, err := os.ReadFile(playlistPath)
+	if err != nil {
+		return nil, fmt.Errorf("read playlist: %w", err)
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		return nil, fmt.Errorf("read segment dir: %w", err)
+	}
+
+	segments := make(map[string]*Segment)
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".ts") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(tmpDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read segment %s: %w", entry.Name(), err)
+		}
+		segments[entry.Name()] = &Segment{Data: data, ContentType: "video/mp2t"}
+	}
+
+	return &Rendition{Playlist: playlist, Segments: segments}, nil
+}
+
+func logStderr(tag string, r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		log.Printf("%s: %s", tag, scanner.Text())
+	}
+}
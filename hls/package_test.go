@@ -0,0 +1,67 @@
+package hls
+
+import (
+	"testing"
+
+	"github.com/tiarhax/instastreamer_go/extractors"
+)
+
+func TestSelectVariants(t *testing.T) {
+	tests := []struct {
+		name        string
+		formats     []extractors.Format
+		maxVariants int
+		wantHeights []int
+	}{
+		{
+			name:        "empty input",
+			formats:     nil,
+			maxVariants: 4,
+			wantHeights: []int{},
+		},
+		{
+			name: "drops formats with no height",
+			formats: []extractors.Format{
+				{FormatID: "audio", Height: 0, Tbr: 128},
+				{FormatID: "video", Height: 720, Tbr: 1500},
+			},
+			maxVariants: 4,
+			wantHeights: []int{720},
+		},
+		{
+			name: "dedupes by height, keeping highest bitrate",
+			formats: []extractors.Format{
+				{FormatID: "720-low", Height: 720, Tbr: 800},
+				{FormatID: "720-high", Height: 720, Tbr: 1500},
+				{FormatID: "1080", Height: 1080, Tbr: 3000},
+			},
+			maxVariants: 4,
+			wantHeights: []int{1080, 720},
+		},
+		{
+			name: "sorts descending and caps at maxVariants",
+			formats: []extractors.Format{
+				{FormatID: "240", Height: 240, Tbr: 300},
+				{FormatID: "480", Height: 480, Tbr: 800},
+				{FormatID: "720", Height: 720, Tbr: 1500},
+				{FormatID: "1080", Height: 1080, Tbr: 3000},
+			},
+			maxVariants: 2,
+			wantHeights: []int{1080, 720},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SelectVariants(tt.formats, tt.maxVariants)
+			if len(got) != len(tt.wantHeights) {
+				t.Fatalf("SelectVariants() returned %d variants, want %d (%v)", len(got), len(tt.wantHeights), got)
+			}
+			for i, h := range tt.wantHeights {
+				if got[i].Height != h {
+					t.Errorf("variant %d height = %d, want %d", i, got[i].Height, h)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Package hls packages a resolved video source into an HLS (HTTP Live
+// Streaming) rendition set so browsers can seek and adapt bitrate, and
+// keeps the packaged output available for later segment requests.
+package hls
+
+import "sync"
+
+// Segment is one .ts chunk of a packaged rendition.
+type Segment struct {
+	Data        []byte
+	ContentType string
+}
+
+// Rendition is a single-bitrate HLS stream: a variant playlist plus its
+// segments, keyed by segment filename (e.g. "seg000.ts").
+type Rendition struct {
+	Playlist []byte
+	Segments map[string]*Segment
+}
+
+// Session is everything needed to serve one packaged HLS stream: a master
+// playlist referencing one Rendition per variant.
+type Session struct {
+	ID         string
+	Master     []byte
+	Renditions map[string]*Rendition // keyed by variant name, e.g. "720p"
+}
+
+// Store holds in-flight HLS sessions so segment requests can be served after
+// the initial packaging request has returned the master playlist URL.
+type Store interface {
+	Put(session *Session)
+	Get(sessionID string) (*Session, bool)
+}
+
+// MemoryStore keeps sessions in process memory. It is sufficient for a
+// single warm Lambda instance; swap in an S3-backed Store if sessions need
+// to survive across instances or cold starts.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]*Session)}
+}
+
+func (s *MemoryStore) Put(session *Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[session.ID] = session
+}
+
+func (s *MemoryStore) Get(sessionID string) (*Session, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	session, ok := s.sessions[sessionID]
+	return session, ok
+}
@@ -1,24 +1,34 @@
 package main
 
 import (
-	"bufio"
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
-	"os/exec"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
+
+	"github.com/tiarhax/instastreamer_go/cache"
+	"github.com/tiarhax/instastreamer_go/extractors"
+	"github.com/tiarhax/instastreamer_go/hls"
+	"github.com/tiarhax/instastreamer_go/tokens"
+	"github.com/tiarhax/instastreamer_go/webhooks"
 )
 
 type VideoInfo struct {
@@ -41,14 +51,64 @@ type AuthResponse struct {
 	Error string `json:"error,omitempty"`
 }
 
+// maxHLSVariants caps how many resolutions we package per HLS session, so a
+// format list with many near-duplicate resolutions doesn't spawn an ffmpeg
+// run for each one.
+const maxHLSVariants = 4
+
+// infoCacheTTL roughly matches the lifetime of Instagram's signed CDN URLs,
+// so a cached VideoInfo doesn't outlive the URL it points to.
+const infoCacheTTL = 30 * time.Minute
+
+// webhookFlushTimeout bounds how long handleStream waits for async webhook
+// deliveries before returning, since Lambda may freeze the process
+// immediately after the response is sent.
+const webhookFlushTimeout = 3 * time.Second
+
 var dynamoClient *dynamodb.Client
 var tableName string
 var httpAdapter *httpadapter.HandlerAdapterV2
+var videoRouter *extractors.Router
+var ytdlpExtractor *extractors.YtDlpExtractor
+var hlsStore *hls.MemoryStore
+var videoCache cache.Cache
+var tokenStore *tokens.Store
+var tokenRateLimiter *tokens.RateLimiter
+var adminSecret string
+var webhookStore *webhooks.Store
+var webhookDispatcher *webhooks.Dispatcher
+
+// errRateLimited signals that a code is valid but over its hourly quota.
+var errRateLimited = errors.New("rate limited")
 
 func init() {
 	// Initialize DynamoDB client
 	initDynamoDB()
 
+	// Initialize the result cache (DynamoDB for info, S3 for bytes); left
+	// nil if caching isn't configured, same as auth above
+	initCache()
+
+	// Initialize the extractor router (Instagram private API, falling back
+	// to yt-dlp; yt-dlp directly for every other supported host)
+	ytdlpExtractor = extractors.NewYtDlpExtractor()
+	videoRouter = extractors.NewRouter(extractors.NewInstagramAPIExtractor(), ytdlpExtractor)
+	hlsStore = hls.NewMemoryStore()
+
+	// Access tokens reuse the auth table; rate limiting is tracked
+	// in-process per warm instance
+	if dynamoClient != nil {
+		tokenStore = tokens.NewStore(dynamoClient, tableName)
+	}
+	tokenRateLimiter = tokens.NewRateLimiter()
+	adminSecret = os.Getenv("ADMIN_SECRET")
+
+	// Webhook subscriptions are keyed on webhook_id, unlike the auth
+	// table's "code" hash key, so they need their own table; nil store
+	// keeps webhookDispatcher.Emit a no-op, same as initCache above.
+	initWebhookStore()
+	webhookDispatcher = initWebhookDispatcher()
+
 	// Create HTTP mux
 	mux := http.NewServeMux()
 
@@ -64,6 +124,17 @@ func init() {
 	// API endpoint to stream video (auth required)
 	mux.HandleFunc("/api/stream", withAuth(handleStream))
 
+	// HLS playlist/segment endpoint, used after ?format=hls kicks off packaging
+	mux.HandleFunc("/api/stream/", withAuth(handleStreamSegment))
+
+	// Admin token management (protected by ADMIN_SECRET, not a user code)
+	mux.HandleFunc("/api/admin/tokens", withAdminAuth(handleAdminTokens))
+	mux.HandleFunc("/api/admin/tokens/", withAdminAuth(handleAdminTokenDelete))
+
+	// Admin webhook subscription management
+	mux.HandleFunc("/api/admin/webhooks", withAdminAuth(handleAdminWebhooks))
+	mux.HandleFunc("/api/admin/webhooks/", withAdminAuth(handleAdminWebhookByID))
+
 	// Create Lambda adapter for API Gateway HTTP API / Lambda Function URL
 	httpAdapter = httpadapter.NewV2(mux)
 }
@@ -83,6 +154,11 @@ func main() {
 		mux.HandleFunc("/api/auth", handleAuth)
 		mux.HandleFunc("/api/info", withAuth(handleVideoInfo))
 		mux.HandleFunc("/api/stream", withAuth(handleStream))
+		mux.HandleFunc("/api/stream/", withAuth(handleStreamSegment))
+		mux.HandleFunc("/api/admin/tokens", withAdminAuth(handleAdminTokens))
+		mux.HandleFunc("/api/admin/tokens/", withAdminAuth(handleAdminTokenDelete))
+		mux.HandleFunc("/api/admin/webhooks", withAdminAuth(handleAdminWebhooks))
+		mux.HandleFunc("/api/admin/webhooks/", withAdminAuth(handleAdminWebhookByID))
 
 		port := ":8080"
 		log.Printf("Server starting on http://localhost%s", port)
@@ -107,6 +183,69 @@ func initDynamoDB() {
 	log.Printf("DynamoDB client initialized, table: %s", tableName)
 }
 
+func initCache() {
+	bucket := os.Getenv("CACHE_S3_BUCKET")
+	if bucket == "" {
+		log.Printf("CACHE_S3_BUCKET not set, result caching disabled")
+		return
+	}
+
+	cacheTableName := os.Getenv("CACHE_DYNAMODB_TABLE")
+	if cacheTableName == "" {
+		cacheTableName = "insta-stream-cache"
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		log.Printf("Warning: Failed to load AWS config for cache: %v", err)
+		return
+	}
+
+	info := cache.NewDynamoInfoCache(dynamodb.NewFromConfig(cfg), cacheTableName)
+	stream := cache.NewS3StreamCache(s3.NewFromConfig(cfg), bucket)
+	videoCache = cache.NewStore(info, stream)
+	log.Printf("Result cache initialized, table: %s, bucket: %s", cacheTableName, bucket)
+}
+
+// initWebhookStore provisions the DynamoDB table backing webhook
+// subscriptions. It has its own table (distinct from the auth/token table)
+// because it's keyed on webhook_id rather than code; left nil if DynamoDB
+// isn't configured, same as tokenStore above.
+func initWebhookStore() {
+	if dynamoClient == nil {
+		return
+	}
+
+	webhookTableName := os.Getenv("WEBHOOK_DYNAMODB_TABLE")
+	if webhookTableName == "" {
+		webhookTableName = "insta-stream-webhooks"
+	}
+
+	webhookStore = webhooks.NewStore(dynamoClient, webhookTableName)
+	log.Printf("Webhook store initialized, table: %s", webhookTableName)
+}
+
+// initWebhookDispatcher builds the delivery worker for webhook events. If
+// WEBHOOK_SQS_QUEUE_URL is set, deliveries are handed off to SQS instead of
+// POSTed inline, so retries survive past this Lambda invocation.
+func initWebhookDispatcher() *webhooks.Dispatcher {
+	dispatcher := webhooks.NewDispatcher(webhookStore)
+
+	queueURL := os.Getenv("WEBHOOK_SQS_QUEUE_URL")
+	if queueURL == "" {
+		return dispatcher
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		log.Printf("Warning: Failed to load AWS config for webhook SQS queue: %v", err)
+		return dispatcher
+	}
+
+	log.Printf("Webhook deliveries handed off to SQS queue: %s", queueURL)
+	return dispatcher.WithSQS(sqs.NewFromConfig(cfg), queueURL)
+}
+
 func withAuth(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Skip auth if DynamoDB is not configured (local dev)
@@ -115,6 +254,11 @@ func withAuth(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		// Tracks any auth.rejected delivery kicked off below, so it can be
+		// flushed before this handler returns even on a rejection path.
+		h := webhooks.NewHandle()
+		defer h.Wait(webhookFlushTimeout)
+
 		// Get auth code from header
 		authCode := r.Header.Get("X-Auth-Code")
 		if authCode == "" {
@@ -123,25 +267,33 @@ func withAuth(next http.HandlerFunc) http.HandlerFunc {
 		}
 
 		if authCode == "" {
+			emitAuthRejected(r, h, "missing auth code")
 			http.Error(w, "Unauthorized: missing auth code", http.StatusUnauthorized)
 			return
 		}
 
 		// Validate code format
 		if !isValidCodeFormat(authCode) {
+			emitAuthRejected(r, h, "invalid auth code format")
 			http.Error(w, "Unauthorized: invalid auth code format", http.StatusUnauthorized)
 			return
 		}
 
-		// Validate against DynamoDB
+		// Validate against the token store
 		valid, _, err := validateCode(authCode)
 		if err != nil {
+			if errors.Is(err, errRateLimited) {
+				emitAuthRejected(r, h, "rate limited")
+				http.Error(w, "Too many requests for this code this hour", http.StatusTooManyRequests)
+				return
+			}
 			log.Printf("Auth validation error: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 
 		if !valid {
+			emitAuthRejected(r, h, "invalid auth code")
 			http.Error(w, "Unauthorized: invalid auth code", http.StatusUnauthorized)
 			return
 		}
@@ -150,6 +302,14 @@ func withAuth(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+func emitAuthRejected(r *http.Request, h *webhooks.Handle, reason string) {
+	webhookDispatcher.Emit(r.Context(), webhooks.Event{
+		Type:      webhooks.EventAuthRejected,
+		Timestamp: time.Now(),
+		Error:     reason,
+	}, h)
+}
+
 func isValidCodeFormat(code string) bool {
 	// Format: XXX-XXX where X is uppercase letter or digit
 	pattern := `^[A-Z0-9]{3}-[A-Z0-9]{3}$`
@@ -157,36 +317,38 @@ func isValidCodeFormat(code string) bool {
 	return matched
 }
 
+// validateCode looks up code in the token store, rejecting disabled or
+// expired tokens and enforcing each token's hourly rate limit, then bumps
+// its usage stats. Legacy tokens without a rate limit set are unlimited.
 func validateCode(code string) (bool, string, error) {
 	if dynamoClient == nil {
 		return true, "Local User", nil
 	}
 
-	// Normalize code to uppercase
 	code = strings.ToUpper(code)
 
-	result, err := dynamoClient.GetItem(context.TODO(), &dynamodb.GetItemInput{
-		TableName: aws.String(tableName),
-		Key: map[string]types.AttributeValue{
-			"code": &types.AttributeValueMemberS{Value: code},
-		},
-	})
-
+	token, err := tokenStore.Get(context.TODO(), code)
 	if err != nil {
 		return false, "", err
 	}
+	if token == nil {
+		return false, "", nil
+	}
 
-	if result.Item == nil {
+	now := time.Now()
+	if token.Disabled || token.Expired(now) {
 		return false, "", nil
 	}
 
-	// Extract name from result
-	name := ""
-	if nameAttr, ok := result.Item["name"].(*types.AttributeValueMemberS); ok {
-		name = nameAttr.Value
+	if !tokenRateLimiter.Allow(token.Code, token.RateLimitPerHour, now) {
+		return false, "", errRateLimited
+	}
+
+	if err := tokenStore.Touch(context.TODO(), token.Code, now); err != nil {
+		log.Printf("tokens: touch %s: %v", token.Code, err)
 	}
 
-	return true, name, nil
+	return true, token.Name, nil
 }
 
 func handleAuth(w http.ResponseWriter, r *http.Request) {
@@ -279,109 +441,589 @@ func handleVideoInfo(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "URL must use http or https scheme", http.StatusBadRequest)
 		return
 	}
-	if !strings.Contains(parsedURL.Host, "instagram.com") {
-		http.Error(w, "URL must be an Instagram URL", http.StatusBadRequest)
+	if !extractors.IsSupportedHost(req.URL) {
+		http.Error(w, "Unsupported URL host", http.StatusBadRequest)
 		return
 	}
 
-	// Use yt-dlp to get video info (JSON output)
-	cmd := exec.Command("yt-dlp", "-j", "--no-warnings", req.URL)
-	output, err := cmd.Output()
+	useCache := videoCache != nil && r.URL.Query().Get("nocache") != "1"
+
+	if useCache {
+		if info, ok := videoCache.GetInfo(r.Context(), req.URL); ok {
+			writeVideoInfoResponse(w, info)
+			return
+		}
+	}
+
+	info, err := videoRouter.Resolve(r.Context(), req.URL)
 	if err != nil {
-		log.Printf("yt-dlp error: %v", err)
+		log.Printf("extractor error: %v", err)
 		http.Error(w, "Failed to get video info", http.StatusInternalServerError)
 		return
 	}
 
-	var info map[string]interface{}
-	if err := json.Unmarshal(output, &info); err != nil {
-		http.Error(w, "Failed to parse video info", http.StatusInternalServerError)
+	if useCache {
+		if err := videoCache.PutInfo(r.Context(), req.URL, info, infoCacheTTL); err != nil {
+			log.Printf("cache: put video info: %v", err)
+		}
+	}
+
+	writeVideoInfoResponse(w, info)
+}
+
+func writeVideoInfoResponse(w http.ResponseWriter, info *extractors.VideoInfo) {
+	response := VideoInfo{
+		URL:       info.URL,
+		Title:     info.Title,
+		Extension: info.Extension,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func handleStream(w http.ResponseWriter, r *http.Request) {
+	videoURL := r.URL.Query().Get("url")
+	if videoURL == "" {
+		http.Error(w, "URL parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	if !extractors.IsSupportedHost(videoURL) {
+		http.Error(w, "Unsupported URL host", http.StatusBadRequest)
 		return
 	}
 
-	// Extract the direct video URL
-	videoURL := ""
-	title := "video"
-	ext := "mp4"
+	// h tracks every webhook delivery this request kicks off (requested,
+	// started, completed/failed), so the deferred Wait below flushes all of
+	// them without blocking on other concurrent requests' deliveries.
+	h := webhooks.NewHandle()
+	webhookDispatcher.Emit(r.Context(), webhooks.Event{
+		Type:      webhooks.EventStreamRequested,
+		Timestamp: time.Now(),
+		URL:       videoURL,
+	}, h)
+	defer h.Wait(webhookFlushTimeout)
+
+	if r.URL.Query().Get("format") == "hls" {
+		handleHLSStart(w, r, h, videoURL)
+		return
+	}
+
+	log.Printf("Streaming video from: %s", videoURL)
+	proxyDirectStream(w, r, h, videoURL)
+}
+
+// proxyDirectStream resolves videoURL's direct CDN URL and reverse-proxies
+// it, forwarding the client's Range header so <video> seeking works against
+// the default MP4 mode. A cache hit is served entirely from cached bytes
+// (via http.ServeContent, so Range still works); a full-file cache miss is
+// teed into the cache as it streams to the client.
+func proxyDirectStream(w http.ResponseWriter, r *http.Request, h *webhooks.Handle, videoURL string) {
+	start := time.Now()
+	useCache := videoCache != nil && r.URL.Query().Get("nocache") != "1"
+	hasRange := r.Header.Get("Range") != ""
+
+	if useCache {
+		if body, ok := videoCache.GetStream(r.Context(), videoURL); ok {
+			defer body.Close()
+			data, err := io.ReadAll(body)
+			if err == nil {
+				emitStreamStarted(r, h, videoURL)
+				w.Header().Set("Content-Type", "video/mp4")
+				http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+				emitStreamCompleted(r, h, videoURL, int64(len(data)), time.Since(start))
+				return
+			}
+			log.Printf("cache: read cached stream: %v", err)
+		}
+	}
 
-	if url, ok := info["url"].(string); ok {
-		videoURL = url
+	info, err := videoRouter.Resolve(r.Context(), videoURL)
+	if err != nil || info.URL == "" {
+		log.Printf("Failed to resolve video: %v", err)
+		emitStreamFailed(r, h, videoURL, fmt.Sprintf("resolve: %v", err))
+		http.Error(w, "Failed to resolve video", http.StatusInternalServerError)
+		return
 	}
-	if t, ok := info["title"].(string); ok {
-		title = t
+
+	upstreamReq, err := http.NewRequestWithContext(r.Context(), http.MethodGet, info.URL, nil)
+	if err != nil {
+		log.Printf("Failed to build upstream request: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
 	}
-	if e, ok := info["ext"].(string); ok {
-		ext = e
+	if hasRange {
+		upstreamReq.Header.Set("Range", r.Header.Get("Range"))
 	}
 
-	response := VideoInfo{
+	resp, err := http.DefaultClient.Do(upstreamReq)
+	if err != nil {
+		log.Printf("Error fetching upstream video: %v", err)
+		emitStreamFailed(r, h, videoURL, fmt.Sprintf("fetch upstream: %v", err))
+		http.Error(w, "Failed to fetch video", http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	for _, header := range []string{"Content-Type", "Content-Length", "Content-Range", "Accept-Ranges"} {
+		if v := resp.Header.Get(header); v != "" {
+			w.Header().Set(header, v)
+		}
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "video/mp4")
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+
+	status := http.StatusOK
+	if resp.StatusCode == http.StatusPartialContent {
+		status = http.StatusPartialContent
+	}
+	w.WriteHeader(status)
+	emitStreamStarted(r, h, videoURL)
+
+	// Only cache full-file responses: caching an arbitrary byte range would
+	// poison the cache with incomplete video.
+	if useCache && !hasRange && resp.StatusCode == http.StatusOK {
+		var buf bytes.Buffer
+		written, err := io.Copy(io.MultiWriter(w, &buf), resp.Body)
+		if err != nil {
+			log.Printf("Error streaming video: %v", err)
+			emitStreamFailed(r, h, videoURL, fmt.Sprintf("stream: %v", err))
+			return
+		}
+		if err := videoCache.PutStream(r.Context(), videoURL, bytes.NewReader(buf.Bytes())); err != nil {
+			log.Printf("cache: put stream: %v", err)
+		}
+		log.Printf("Streamed %d bytes from %s", written, videoURL)
+		emitStreamCompleted(r, h, videoURL, written, time.Since(start))
+		return
+	}
+
+	written, err := io.Copy(w, resp.Body)
+	if err != nil {
+		log.Printf("Error streaming video: %v", err)
+		emitStreamFailed(r, h, videoURL, fmt.Sprintf("stream: %v", err))
+		return
+	}
+
+	log.Printf("Streamed %d bytes from %s", written, videoURL)
+	emitStreamCompleted(r, h, videoURL, written, time.Since(start))
+}
+
+func emitStreamStarted(r *http.Request, h *webhooks.Handle, videoURL string) {
+	webhookDispatcher.Emit(r.Context(), webhooks.Event{
+		Type:      webhooks.EventStreamStarted,
+		Timestamp: time.Now(),
+		URL:       videoURL,
+	}, h)
+}
+
+func emitStreamCompleted(r *http.Request, h *webhooks.Handle, videoURL string, bytesWritten int64, duration time.Duration) {
+	webhookDispatcher.Emit(r.Context(), webhooks.Event{
+		Type:         webhooks.EventStreamCompleted,
+		Timestamp:    time.Now(),
+		URL:          videoURL,
+		BytesWritten: bytesWritten,
+		DurationMS:   duration.Milliseconds(),
+	}, h)
+}
+
+func emitStreamFailed(r *http.Request, h *webhooks.Handle, videoURL, reason string) {
+	webhookDispatcher.Emit(r.Context(), webhooks.Event{
+		Type:      webhooks.EventStreamFailed,
+		Timestamp: time.Now(),
 		URL:       videoURL,
-		Title:     title,
-		Extension: ext,
+		Error:     reason,
+	}, h)
+}
+
+// handleHLSStart packages videoURL into an HLS session (one rendition per
+// resolution) and redirects the client to its master playlist.
+func handleHLSStart(w http.ResponseWriter, r *http.Request, h *webhooks.Handle, videoURL string) {
+	formats, err := ytdlpExtractor.ListFormats(r.Context(), videoURL)
+	if err != nil {
+		log.Printf("hls: list formats: %v", err)
+		emitStreamFailed(r, h, videoURL, fmt.Sprintf("list formats: %v", err))
+		http.Error(w, "Failed to list video formats", http.StatusInternalServerError)
+		return
+	}
+
+	variants := hls.SelectVariants(formats, maxHLSVariants)
+	if len(variants) == 0 {
+		emitStreamFailed(r, h, videoURL, "no usable formats for HLS packaging")
+		http.Error(w, "No usable formats for HLS packaging", http.StatusUnprocessableEntity)
+		return
+	}
+
+	sessionID, err := newSessionID()
+	if err != nil {
+		log.Printf("hls: generate session id: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	session, err := hls.Package(r.Context(), sessionID, videoURL, variants)
+	if err != nil {
+		log.Printf("hls: package: %v", err)
+		emitStreamFailed(r, h, videoURL, fmt.Sprintf("package: %v", err))
+		http.Error(w, "Failed to package video for HLS", http.StatusInternalServerError)
+		return
+	}
+
+	hlsStore.Put(session)
+	emitStreamStarted(r, h, videoURL)
+	http.Redirect(w, r, "/api/stream/"+sessionID+"/master.m3u8", http.StatusFound)
+}
+
+// handleStreamSegment serves the master playlist, per-variant playlists,
+// and .ts segments produced by handleHLSStart, under
+// /api/stream/{sessionID}/{...}.
+func handleStreamSegment(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/stream/")
+	sessionID, subPath, _ := strings.Cut(rest, "/")
+	if sessionID == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	session, ok := hlsStore.Get(sessionID)
+	if !ok {
+		http.Error(w, "Unknown or expired stream session", http.StatusNotFound)
+		return
+	}
+
+	if subPath == "" || subPath == "master.m3u8" {
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		w.Write(session.Master)
+		return
+	}
+
+	variant, file, ok := strings.Cut(subPath, "/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	rendition, ok := session.Renditions[variant]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if file == "playlist.m3u8" {
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		w.Write(rendition.Playlist)
+		return
+	}
+
+	segment, ok := rendition.Segments[file]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", segment.ContentType)
+	w.Write(segment.Data)
+}
+
+func newSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// withAdminAuth protects the token-management endpoints with a bearer
+// token compared against ADMIN_SECRET, separate from the per-client access
+// tokens that withAuth checks.
+func withAdminAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if adminSecret == "" {
+			http.Error(w, "Admin API is not configured", http.StatusServiceUnavailable)
+			return
+		}
+
+		const prefix = "Bearer "
+		authHeader := r.Header.Get("Authorization")
+		if !strings.HasPrefix(authHeader, prefix) || authHeader[len(prefix):] != adminSecret {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+type createTokenRequest struct {
+	Name             string   `json:"name"`
+	Scopes           []string `json:"scopes"`
+	ExpiresAt        string   `json:"expires_at"` // RFC3339, optional
+	RateLimitPerHour int      `json:"rate_limit_per_hour"`
+}
+
+func handleAdminTokens(w http.ResponseWriter, r *http.Request) {
+	if tokenStore == nil {
+		http.Error(w, "Token store is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		createToken(w, r)
+	case http.MethodGet:
+		listTokens(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func createToken(w http.ResponseWriter, r *http.Request) {
+	var req createTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	token := &tokens.Token{
+		Name:             req.Name,
+		Scopes:           req.Scopes,
+		CreatedAt:        time.Now(),
+		RateLimitPerHour: req.RateLimitPerHour,
+	}
+
+	if req.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			http.Error(w, "Invalid expires_at, expected RFC3339", http.StatusBadRequest)
+			return
+		}
+		token.ExpiresAt = &expiresAt
+	}
+
+	code, err := newTokenCode()
+	if err != nil {
+		log.Printf("tokens: generate code: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	token.Code = code
+
+	if err := tokenStore.Create(r.Context(), token); err != nil {
+		log.Printf("tokens: create: %v", err)
+		http.Error(w, "Failed to create token", http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(token)
 }
 
-func handleStream(w http.ResponseWriter, r *http.Request) {
-	instagramURL := r.URL.Query().Get("url")
-	if instagramURL == "" {
-		http.Error(w, "URL parameter is required", http.StatusBadRequest)
+func listTokens(w http.ResponseWriter, r *http.Request) {
+	list, err := tokenStore.List(r.Context())
+	if err != nil {
+		log.Printf("tokens: list: %v", err)
+		http.Error(w, "Failed to list tokens", http.StatusInternalServerError)
 		return
 	}
 
-	log.Printf("Streaming video from: %s", instagramURL)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
 
-	// Use yt-dlp to output video to stdout
-	cmd := exec.Command("yt-dlp", "-o", "-", "--no-warnings", "-f", "best", instagramURL)
+func handleAdminTokenDelete(w http.ResponseWriter, r *http.Request) {
+	if tokenStore == nil {
+		http.Error(w, "Token store is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-	stdout, err := cmd.StdoutPipe()
+	code := strings.TrimPrefix(r.URL.Path, "/api/admin/tokens/")
+	if code == "" {
+		http.Error(w, "Token code is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := tokenStore.Delete(r.Context(), strings.ToUpper(code)); err != nil {
+		log.Printf("tokens: delete: %v", err)
+		http.Error(w, "Failed to delete token", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newTokenCode generates a random XXX-XXX code, matching the format
+// isValidCodeFormat expects.
+func newTokenCode() (string, error) {
+	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i := range buf {
+		buf[i] = charset[int(buf[i])%len(charset)]
+	}
+	return fmt.Sprintf("%s-%s", buf[:3], buf[3:]), nil
+}
+
+type createWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+}
+
+func handleAdminWebhooks(w http.ResponseWriter, r *http.Request) {
+	if webhookStore == nil {
+		http.Error(w, "Webhook store is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		createWebhook(w, r)
+	case http.MethodGet:
+		listWebhooks(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func createWebhook(w http.ResponseWriter, r *http.Request) {
+	var req createWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.URL == "" || len(req.EventTypes) == 0 {
+		http.Error(w, "url and event_types are required", http.StatusBadRequest)
+		return
+	}
+
+	eventTypes := make([]webhooks.EventType, len(req.EventTypes))
+	for i, et := range req.EventTypes {
+		eventTypes[i] = webhooks.EventType(et)
+	}
+
+	webhookID, err := newWebhookID()
 	if err != nil {
-		log.Printf("Failed to create stdout pipe: %v", err)
-		http.Error(w, "Failed to start download", http.StatusInternalServerError)
+		log.Printf("webhooks: generate id: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	stderr, err := cmd.StderrPipe()
+	secret, err := newWebhookSecret()
 	if err != nil {
-		log.Printf("Failed to create stderr pipe: %v", err)
-		http.Error(w, "Failed to start download", http.StatusInternalServerError)
+		log.Printf("webhooks: generate secret: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	if err := cmd.Start(); err != nil {
-		log.Printf("Failed to start yt-dlp: %v", err)
-		http.Error(w, "Failed to start download", http.StatusInternalServerError)
+	sub := &webhooks.Subscription{
+		WebhookID:  webhookID,
+		URL:        req.URL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := webhookStore.Create(r.Context(), sub); err != nil {
+		log.Printf("webhooks: create: %v", err)
+		http.Error(w, "Failed to create webhook", http.StatusInternalServerError)
 		return
 	}
 
-	// Log stderr in background
-	go func() {
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			line := scanner.Text()
-			if !strings.Contains(line, "[download]") {
-				log.Printf("yt-dlp: %s", line)
-			}
-		}
-	}()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sub)
+}
 
-	// Set headers for video streaming
-	w.Header().Set("Content-Type", "video/mp4")
-	w.Header().Set("Transfer-Encoding", "chunked")
-	w.Header().Set("Cache-Control", "no-cache")
+func listWebhooks(w http.ResponseWriter, r *http.Request) {
+	list, err := webhookStore.List(r.Context())
+	if err != nil {
+		log.Printf("webhooks: list: %v", err)
+		http.Error(w, "Failed to list webhooks", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// handleAdminWebhookByID handles DELETE /api/admin/webhooks/{id} and, with
+// ?test=1, POST /api/admin/webhooks/{id}?test=1 to fire a sample delivery
+// without waiting for a real stream event.
+func handleAdminWebhookByID(w http.ResponseWriter, r *http.Request) {
+	if webhookStore == nil {
+		http.Error(w, "Webhook store is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	webhookID := strings.TrimPrefix(r.URL.Path, "/api/admin/webhooks/")
+	if webhookID == "" {
+		http.Error(w, "Webhook id is required", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodPost && r.URL.Query().Get("test") == "1" {
+		testWebhook(w, r, webhookID)
+		return
+	}
 
-	// Stream the video directly to the response
-	written, err := io.Copy(w, stdout)
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := webhookStore.Delete(r.Context(), webhookID); err != nil {
+		log.Printf("webhooks: delete: %v", err)
+		http.Error(w, "Failed to delete webhook", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func testWebhook(w http.ResponseWriter, r *http.Request, webhookID string) {
+	sub, err := webhookStore.Get(r.Context(), webhookID)
 	if err != nil {
-		log.Printf("Error streaming video: %v", err)
+		log.Printf("webhooks: get %s: %v", webhookID, err)
+		http.Error(w, "Failed to load webhook", http.StatusInternalServerError)
+		return
+	}
+	if sub == nil {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
 		return
 	}
 
-	if err := cmd.Wait(); err != nil {
-		log.Printf("yt-dlp process error: %v", err)
+	h := webhooks.NewHandle()
+	webhookDispatcher.Test(r.Context(), sub, h)
+	h.Wait(webhookFlushTimeout)
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// newWebhookID generates a random 16-byte hex id, matching the style of
+// newSessionID.
+func newWebhookID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
 	}
+	return hex.EncodeToString(buf), nil
+}
 
-	log.Printf("Streamed %d bytes", written)
+// newWebhookSecret generates the shared secret used to HMAC-sign delivered
+// payloads.
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
 }
@@ -0,0 +1,72 @@
+package tokens
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepInterval bounds how often Allow scans for expired windows, so a warm
+// instance juggling many distinct codes doesn't grow counters forever while
+// still keeping the common-case call cheap.
+const sweepInterval = 10 * time.Minute
+
+// RateLimiter enforces a per-code hourly request budget using an in-memory
+// counter. It's scoped to a single warm Lambda/process instance: good
+// enough to blunt abuse between requests without a DynamoDB round trip on
+// every call.
+type RateLimiter struct {
+	mu        sync.Mutex
+	counters  map[string]*hourlyCounter
+	lastSweep time.Time
+}
+
+type hourlyCounter struct {
+	windowStart time.Time
+	count       int
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{counters: make(map[string]*hourlyCounter)}
+}
+
+// Allow reports whether code may make another request this hour. A limit
+// of 0 means unlimited.
+func (r *RateLimiter) Allow(code string, limit int, now time.Time) bool {
+	if limit <= 0 {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sweepExpiredLocked(now)
+
+	counter, ok := r.counters[code]
+	if !ok || now.Sub(counter.windowStart) >= time.Hour {
+		counter = &hourlyCounter{windowStart: now}
+		r.counters[code] = counter
+	}
+
+	if counter.count >= limit {
+		return false
+	}
+	counter.count++
+	return true
+}
+
+// sweepExpiredLocked evicts counters whose hourly window has expired (it
+// started an hour or more ago).
+// Called with mu held; it's a no-op unless sweepInterval has elapsed since
+// the last sweep, so it doesn't turn every Allow call into an O(n) scan.
+func (r *RateLimiter) sweepExpiredLocked(now time.Time) {
+	if now.Sub(r.lastSweep) < sweepInterval {
+		return
+	}
+	r.lastSweep = now
+
+	for code, counter := range r.counters {
+		if now.Sub(counter.windowStart) >= time.Hour {
+			delete(r.counters, code)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("unlimited when limit is zero", func(t *testing.T) {
+		r := NewRateLimiter()
+		for i := 0; i < 100; i++ {
+			if !r.Allow("CODE", 0, base) {
+				t.Fatalf("Allow() = false with limit 0 on call %d, want true", i)
+			}
+		}
+	})
+
+	t.Run("blocks once limit is reached within the window", func(t *testing.T) {
+		r := NewRateLimiter()
+		for i := 0; i < 3; i++ {
+			if !r.Allow("CODE", 3, base) {
+				t.Fatalf("Allow() = false on call %d, want true (within limit)", i)
+			}
+		}
+		if r.Allow("CODE", 3, base) {
+			t.Fatal("Allow() = true after limit exhausted, want false")
+		}
+	})
+
+	t.Run("resets after the hourly window rolls over", func(t *testing.T) {
+		r := NewRateLimiter()
+		for i := 0; i < 2; i++ {
+			r.Allow("CODE", 2, base)
+		}
+		if r.Allow("CODE", 2, base) {
+			t.Fatal("Allow() = true before window rollover, want false")
+		}
+		if !r.Allow("CODE", 2, base.Add(time.Hour)) {
+			t.Fatal("Allow() = false after window rollover, want true")
+		}
+	})
+
+	t.Run("tracks codes independently", func(t *testing.T) {
+		r := NewRateLimiter()
+		r.Allow("A", 1, base)
+		if !r.Allow("B", 1, base) {
+			t.Fatal("Allow() for a different code = false, want true")
+		}
+		if r.Allow("A", 1, base) {
+			t.Fatal("Allow() for exhausted code A = true, want false")
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Store manages access tokens in DynamoDB, keyed on the token's code.
+// Legacy items written before usage tracking existed (no use_count,
+// expires_at, etc.) decode as unlimited, non-expiring tokens.
+type Store struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewStore(client *dynamodb.Client, tableName string) *Store {
+	return &Store{client: client, tableName: tableName}
+}
+
+// Create writes a new token. Callers are responsible for generating a
+// unique code.
+func (s *Store) Create(ctx context.Context, t *Token) error {
+	item := map[string]types.AttributeValue{
+		"code":       &types.AttributeValueMemberS{Value: t.Code},
+		"created_at": &types.AttributeValueMemberS{Value: t.CreatedAt.Format(time.RFC3339)},
+		"use_count":  &types.AttributeValueMemberN{Value: "0"},
+		"disabled":   &types.AttributeValueMemberBOOL{Value: false},
+	}
+	if t.Name != "" {
+		item["name"] = &types.AttributeValueMemberS{Value: t.Name}
+	}
+	if len(t.Scopes) > 0 {
+		scopeValues := make([]types.AttributeValue, len(t.Scopes))
+		for i, scope := range t.Scopes {
+			scopeValues[i] = &types.AttributeValueMemberS{Value: scope}
+		}
+		item["scopes"] = &types.AttributeValueMemberL{Value: scopeValues}
+	}
+	if t.ExpiresAt != nil {
+		item["expires_at"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(t.ExpiresAt.Unix(), 10)}
+	}
+	if t.RateLimitPerHour > 0 {
+		item["rate_limit_per_hour"] = &types.AttributeValueMemberN{Value: strconv.Itoa(t.RateLimitPerHour)}
+	}
+
+	_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("tokens: create: %w", err)
+	}
+	return nil
+}
+
+// List scans the table for every token. Fine at the expected scale of an
+// admin-issued token table; switch to a GSI-backed query if it grows large.
+func (s *Store) List(ctx context.Context) ([]*Token, error) {
+	out, err := s.client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String(s.tableName)})
+	if err != nil {
+		return nil, fmt.Errorf("tokens: list: %w", err)
+	}
+
+	list := make([]*Token, 0, len(out.Items))
+	for _, item := range out.Items {
+		list = append(list, fromItem(item))
+	}
+	return list, nil
+}
+
+// Delete removes a token by code.
+func (s *Store) Delete(ctx context.Context, code string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"code": &types.AttributeValueMemberS{Value: code},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("tokens: delete: %w", err)
+	}
+	return nil
+}
+
+// Get fetches a single token by code, or nil if it doesn't exist.
+func (s *Store) Get(ctx context.Context, code string) (*Token, error) {
+	out, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"code": &types.AttributeValueMemberS{Value: code},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("tokens: get: %w", err)
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+	return fromItem(out.Item), nil
+}
+
+// Touch bumps last_used and use_count atomically via a conditional
+// UpdateItem. Legacy items without a use_count attribute start incrementing
+// from zero via DynamoDB's ADD semantics.
+func (s *Store) Touch(ctx context.Context, code string, now time.Time) error {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"code": &types.AttributeValueMemberS{Value: code},
+		},
+		UpdateExpression: aws.String("SET last_used = :now ADD use_count :one"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberS{Value: now.Format(time.RFC3339)},
+			":one": &types.AttributeValueMemberN{Value: "1"},
+		},
+		ConditionExpression: aws.String("attribute_exists(code)"),
+	})
+	if err != nil {
+		return fmt.Errorf("tokens: touch: %w", err)
+	}
+	return nil
+}
+
+func fromItem(item map[string]types.AttributeValue) *Token {
+	t := &Token{}
+
+	if v, ok := item["code"].(*types.AttributeValueMemberS); ok {
+		t.Code = v.Value
+	}
+	if v, ok := item["name"].(*types.AttributeValueMemberS); ok {
+		t.Name = v.Value
+	}
+	if v, ok := item["created_at"].(*types.AttributeValueMemberS); ok {
+		if parsed, err := time.Parse(time.RFC3339, v.Value); err == nil {
+			t.CreatedAt = parsed
+		}
+	}
+	if v, ok := item["last_used"].(*types.AttributeValueMemberS); ok {
+		if parsed, err := time.Parse(time.RFC3339, v.Value); err == nil {
+			t.LastUsed = &parsed
+		}
+	}
+	if v, ok := item["use_count"].(*types.AttributeValueMemberN); ok {
+		if n, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+			t.UseCount = n
+		}
+	}
+	if v, ok := item["expires_at"].(*types.AttributeValueMemberN); ok {
+		if n, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+			expires := time.Unix(n, 0)
+			t.ExpiresAt = &expires
+		}
+	}
+	if v, ok := item["rate_limit_per_hour"].(*types.AttributeValueMemberN); ok {
+		if n, err := strconv.Atoi(v.Value); err == nil {
+			t.RateLimitPerHour = n
+		}
+	}
+	if v, ok := item["disabled"].(*types.AttributeValueMemberBOOL); ok {
+		t.Disabled = v.Value
+	}
+	if v, ok := item["scopes"].(*types.AttributeValueMemberL); ok {
+		for _, s := range v.Value {
+			if sv, ok := s.(*types.AttributeValueMemberS); ok {
+				t.Scopes = append(t.Scopes, sv.Value)
+			}
+		}
+	}
+
+	return t
+}
@@ -0,0 +1,25 @@
+// Package tokens implements admin-issued API access tokens: the XXX-XXX
+// codes now carry usage tracking, expiry, scopes, and per-code rate limits
+// instead of being a flat allow/deny table.
+package tokens
+
+import "time"
+
+// Token is an access token issued to a client of the API.
+type Token struct {
+	Code             string     `json:"code"`
+	Name             string     `json:"name,omitempty"`
+	Scopes           []string   `json:"scopes,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	RateLimitPerHour int        `json:"rate_limit_per_hour,omitempty"`
+	Disabled         bool       `json:"disabled"`
+	LastUsed         *time.Time `json:"last_used,omitempty"`
+	UseCount         int64      `json:"use_count"`
+}
+
+// Expired reports whether the token is past its expiry, if any. A token
+// with no ExpiresAt never expires.
+func (t *Token) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && now.After(*t.ExpiresAt)
+}
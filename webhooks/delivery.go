@@ -0,0 +1,216 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// maxDeliveryAttempts bounds the exponential-backoff retry loop for a
+// single delivery.
+const maxDeliveryAttempts = 5
+
+// Dispatcher fans stream lifecycle events out to every subscription that
+// wants them. Deliveries happen on background goroutines tracked by the
+// Handle passed to Emit/Test; call Handle.Wait before a Lambda invocation
+// returns so the runtime doesn't freeze the process mid-delivery.
+type Dispatcher struct {
+	store      *Store
+	httpClient *http.Client
+
+	sqsClient *sqs.Client
+	queueURL  string
+}
+
+// Handle tracks the in-flight deliveries kicked off by one logical request.
+// It exists because a Dispatcher is shared by every concurrent request: a
+// dispatcher-wide WaitGroup would make one request's Wait block on (or
+// silently race ahead of) another request's unrelated deliveries. Each
+// caller should create its own Handle, thread it through every Emit/Test
+// call for that request, and Wait on it once before returning.
+type Handle struct {
+	wg sync.WaitGroup
+}
+
+// NewHandle returns a Handle tracking no deliveries yet.
+func NewHandle() *Handle {
+	return &Handle{}
+}
+
+// Wait blocks until every delivery tracked by h finishes or timeout
+// elapses, whichever comes first.
+func (h *Handle) Wait(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Printf("webhooks: flush timed out after %s, some deliveries may still be in flight", timeout)
+	}
+}
+
+func NewDispatcher(store *Store) *Dispatcher {
+	return &Dispatcher{
+		store:      store,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// WithSQS hands delivery off to an SQS queue instead of POSTing inline,
+// for callers that run a separate worker to absorb retries outside the
+// Lambda's execution window.
+func (d *Dispatcher) WithSQS(client *sqs.Client, queueURL string) *Dispatcher {
+	d.sqsClient = client
+	d.queueURL = queueURL
+	return d
+}
+
+// Emit looks up subscriptions for event.Type and delivers to each
+// asynchronously, tracking the deliveries on h so the caller can Wait for
+// them. A nil store (webhooks not configured) is a no-op.
+func (d *Dispatcher) Emit(ctx context.Context, event Event, h *Handle) {
+	if d.store == nil {
+		return
+	}
+
+	subs, err := d.store.List(ctx)
+	if err != nil {
+		log.Printf("webhooks: list subscriptions: %v", err)
+		return
+	}
+
+	for _, sub := range subs {
+		if !sub.Wants(event.Type) {
+			continue
+		}
+		sub := sub
+		h.wg.Add(1)
+		go func() {
+			defer h.wg.Done()
+			d.deliver(ctx, sub, event)
+		}()
+	}
+}
+
+// Test delivers a sample payload directly to sub, bypassing the event-type
+// filtering Emit does, so an admin can confirm an endpoint is reachable
+// without waiting for a real stream event. The delivery is tracked on h.
+func (d *Dispatcher) Test(ctx context.Context, sub *Subscription, h *Handle) {
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		d.deliver(ctx, sub, Event{
+			Type:         EventStreamCompleted,
+			Timestamp:    time.Now(),
+			URL:          "https://example.com/sample-video",
+			BytesWritten: 1024,
+			DurationMS:   250,
+		})
+	}()
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, sub *Subscription, event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("webhooks: marshal event: %v", err)
+		return
+	}
+
+	if d.sqsClient != nil {
+		if err := d.enqueueSQS(ctx, sub, body); err != nil {
+			log.Printf("webhooks: enqueue %s to SQS: %v", sub.WebhookID, err)
+		}
+		return
+	}
+
+	signature := sign(sub.Secret, body)
+
+	var lastStatus int
+	for attempt := 0; attempt < maxDeliveryAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff(attempt))
+		}
+
+		status, err := d.post(ctx, sub.URL, body, signature)
+		lastStatus = status
+		if err == nil && status < 300 {
+			break
+		}
+		log.Printf("webhooks: deliver %s to %s (attempt %d/%d): status=%d err=%v",
+			event.Type, sub.WebhookID, attempt+1, maxDeliveryAttempts, status, err)
+	}
+
+	if err := d.store.Touch(ctx, sub.WebhookID, time.Now(), lastStatus); err != nil {
+		log.Printf("webhooks: touch %s: %v", sub.WebhookID, err)
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, url string, body []byte, signature string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature", signature)
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) enqueueSQS(ctx context.Context, sub *Subscription, body []byte) error {
+	_, err := d.sqsClient.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    aws.String(d.queueURL),
+		MessageBody: aws.String(string(body)),
+		MessageAttributes: map[string]sqstypes.MessageAttributeValue{
+			"webhook_id": {DataType: aws.String("String"), StringValue: aws.String(sub.WebhookID)},
+			"url":        {DataType: aws.String("String"), StringValue: aws.String(sub.URL)},
+			"signature":  {DataType: aws.String("String"), StringValue: aws.String(sign(sub.Secret, body))},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("sqs send: %w", err)
+	}
+	return nil
+}
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// maxBackoff caps a single retry delay so the sum across maxDeliveryAttempts
+// stays well under callers' flush windows (a few seconds, since Lambda can
+// freeze the process once the handler returns) instead of the uncapped
+// exponential growing past it before a single delivery chain finishes.
+const maxBackoff = 500 * time.Millisecond
+
+func backoff(attempt int) time.Duration {
+	d := (1 << uint(attempt-1)) * 150 * time.Millisecond
+	if d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
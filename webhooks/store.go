@@ -0,0 +1,151 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Store manages webhook subscriptions in DynamoDB, keyed on webhook_id.
+type Store struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewStore(client *dynamodb.Client, tableName string) *Store {
+	return &Store{client: client, tableName: tableName}
+}
+
+// Create writes a new subscription. Callers are responsible for generating
+// a unique WebhookID.
+func (s *Store) Create(ctx context.Context, sub *Subscription) error {
+	eventTypeValues := make([]types.AttributeValue, len(sub.EventTypes))
+	for i, et := range sub.EventTypes {
+		eventTypeValues[i] = &types.AttributeValueMemberS{Value: string(et)}
+	}
+
+	item := map[string]types.AttributeValue{
+		"webhook_id":  &types.AttributeValueMemberS{Value: sub.WebhookID},
+		"url":         &types.AttributeValueMemberS{Value: sub.URL},
+		"event_types": &types.AttributeValueMemberL{Value: eventTypeValues},
+		"secret":      &types.AttributeValueMemberS{Value: sub.Secret},
+		"created_at":  &types.AttributeValueMemberS{Value: sub.CreatedAt.Format(time.RFC3339)},
+	}
+
+	_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("webhooks: create: %w", err)
+	}
+	return nil
+}
+
+// List scans the table for every subscription.
+func (s *Store) List(ctx context.Context) ([]*Subscription, error) {
+	out, err := s.client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String(s.tableName)})
+	if err != nil {
+		return nil, fmt.Errorf("webhooks: list: %w", err)
+	}
+
+	list := make([]*Subscription, 0, len(out.Items))
+	for _, item := range out.Items {
+		list = append(list, fromItem(item))
+	}
+	return list, nil
+}
+
+// Get fetches a single subscription by id, or nil if it doesn't exist.
+func (s *Store) Get(ctx context.Context, webhookID string) (*Subscription, error) {
+	out, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"webhook_id": &types.AttributeValueMemberS{Value: webhookID},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("webhooks: get: %w", err)
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+	return fromItem(out.Item), nil
+}
+
+// Delete removes a subscription by id.
+func (s *Store) Delete(ctx context.Context, webhookID string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"webhook_id": &types.AttributeValueMemberS{Value: webhookID},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("webhooks: delete: %w", err)
+	}
+	return nil
+}
+
+// Touch records the outcome of the most recent delivery attempt.
+func (s *Store) Touch(ctx context.Context, webhookID string, now time.Time, status int) error {
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"webhook_id": &types.AttributeValueMemberS{Value: webhookID},
+		},
+		UpdateExpression: aws.String("SET last_used = :now, last_status = :status"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now":    &types.AttributeValueMemberS{Value: now.Format(time.RFC3339)},
+			":status": &types.AttributeValueMemberN{Value: strconv.Itoa(status)},
+		},
+		ConditionExpression: aws.String("attribute_exists(webhook_id)"),
+	})
+	if err != nil {
+		return fmt.Errorf("webhooks: touch: %w", err)
+	}
+	return nil
+}
+
+func fromItem(item map[string]types.AttributeValue) *Subscription {
+	sub := &Subscription{}
+
+	if v, ok := item["webhook_id"].(*types.AttributeValueMemberS); ok {
+		sub.WebhookID = v.Value
+	}
+	if v, ok := item["url"].(*types.AttributeValueMemberS); ok {
+		sub.URL = v.Value
+	}
+	if v, ok := item["secret"].(*types.AttributeValueMemberS); ok {
+		sub.Secret = v.Value
+	}
+	if v, ok := item["created_at"].(*types.AttributeValueMemberS); ok {
+		if parsed, err := time.Parse(time.RFC3339, v.Value); err == nil {
+			sub.CreatedAt = parsed
+		}
+	}
+	if v, ok := item["last_used"].(*types.AttributeValueMemberS); ok {
+		if parsed, err := time.Parse(time.RFC3339, v.Value); err == nil {
+			sub.LastUsed = &parsed
+		}
+	}
+	if v, ok := item["last_status"].(*types.AttributeValueMemberN); ok {
+		if n, err := strconv.Atoi(v.Value); err == nil {
+			sub.LastStatus = n
+		}
+	}
+	if v, ok := item["event_types"].(*types.AttributeValueMemberL); ok {
+		for _, e := range v.Value {
+			if ev, ok := e.(*types.AttributeValueMemberS); ok {
+				sub.EventTypes = append(sub.EventTypes, EventType(ev.Value))
+			}
+		}
+	}
+
+	return sub
+}
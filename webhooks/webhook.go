@@ -0,0 +1,49 @@
+// Package webhooks delivers stream lifecycle events to admin-registered
+// HTTP endpoints, signing each payload with the subscription's secret so
+// receivers can verify authenticity.
+package webhooks
+
+import "time"
+
+// EventType identifies what happened during a stream request.
+type EventType string
+
+const (
+	EventStreamRequested EventType = "stream.requested"
+	EventStreamStarted   EventType = "stream.started"
+	EventStreamCompleted EventType = "stream.completed"
+	EventStreamFailed    EventType = "stream.failed"
+	EventAuthRejected    EventType = "auth.rejected"
+)
+
+// Event is the JSON payload delivered to subscribers. Only the fields
+// relevant to Type are populated.
+type Event struct {
+	Type         EventType `json:"type"`
+	Timestamp    time.Time `json:"timestamp"`
+	URL          string    `json:"url,omitempty"`
+	BytesWritten int64     `json:"bytes_written,omitempty"`
+	DurationMS   int64     `json:"duration_ms,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// Subscription is an admin-registered webhook endpoint.
+type Subscription struct {
+	WebhookID  string      `json:"webhook_id"`
+	URL        string      `json:"url"`
+	EventTypes []EventType `json:"event_types"`
+	Secret     string      `json:"secret"`
+	CreatedAt  time.Time   `json:"created_at"`
+	LastUsed   *time.Time  `json:"last_used,omitempty"`
+	LastStatus int         `json:"last_status,omitempty"`
+}
+
+// Wants reports whether the subscription is registered for t.
+func (s *Subscription) Wants(t EventType) bool {
+	for _, want := range s.EventTypes {
+		if want == t {
+			return true
+		}
+	}
+	return false
+}